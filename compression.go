@@ -0,0 +1,80 @@
+package har
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// decompressBody undoes whatever Content-Encoding the transfer used, so that
+// Content.Text/Content.Size reflect the actual resource rather than its
+// on-the-wire representation, per the HAR spec.
+func decompressBody(contentEncoding string, body []byte) ([]byte, error) {
+	switch strings.ToLower(strings.TrimSpace(contentEncoding)) {
+	case "", "identity":
+		return body, nil
+
+	case "gzip":
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("creating gzip reader: %w", err)
+		}
+		defer zr.Close()
+
+		return io.ReadAll(zr)
+
+	case "deflate":
+		zr := flate.NewReader(bytes.NewReader(body))
+		defer zr.Close()
+
+		return io.ReadAll(zr)
+
+	case "br":
+		return io.ReadAll(brotli.NewReader(bytes.NewReader(body)))
+
+	case "zstd":
+		zr, err := zstd.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("creating zstd reader: %w", err)
+		}
+		defer zr.Close()
+
+		return io.ReadAll(zr)
+
+	default:
+		// Unknown encoding: leave the body untouched rather than fail the
+		// whole capture.
+		return body, nil
+	}
+}
+
+// headerBlockSize re-serializes a "start line" (request or status line) plus
+// a header block the way it would appear on the wire: "<startLine>\r\n" +
+// one "Name: Value\r\n" per header + a trailing "\r\n". This is what
+// HeadersSize is supposed to measure per the HAR spec.
+func headerBlockSize(startLine string, header http.Header) int64 {
+	var b strings.Builder
+	b.WriteString(startLine)
+	b.WriteString("\r\n")
+	_ = header.Write(&b)
+	b.WriteString("\r\n")
+
+	return int64(b.Len())
+}
+
+// requestHeaderBlockSize is headerBlockSize for a client request.
+func requestHeaderBlockSize(r *http.Request) int64 {
+	return headerBlockSize(fmt.Sprintf("%s %s %s", r.Method, r.URL.RequestURI(), r.Proto), r.Header)
+}
+
+// responseHeaderBlockSize is headerBlockSize for a client response.
+func responseHeaderBlockSize(resp *http.Response) int64 {
+	return headerBlockSize(fmt.Sprintf("%s %s", resp.Proto, resp.Status), resp.Header)
+}
@@ -0,0 +1,71 @@
+package har_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aidansteele/har"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// net/http.Transport transparently decompresses gzip and strips
+// Content-Encoding before RoundTrip ever sees the response, unless the
+// caller opted out via DisableCompression. A real client that wants HAR's
+// own decompression (and an accurate Content.Compression) needs to set
+// that, the same way a proxy inspecting on-the-wire bytes would.
+func TestRoundTripper_DecompressesGzipResponse(t *testing.T) {
+	plaintext := []byte(`{"hello":"world"}`)
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+
+		gz := gzip.NewWriter(w)
+		_, _ = gz.Write(plaintext)
+		_ = gz.Close()
+	}))
+	defer upstream.Close()
+
+	buf := &bytes.Buffer{}
+	inner := &http.Transport{DisableCompression: true}
+	rt, err := har.New(inner, buf, nil)
+	require.NoError(t, err)
+
+	c := &http.Client{Transport: rt}
+	resp, err := c.Get(upstream.URL)
+	require.NoError(t, err)
+	assert.NotNil(t, resp)
+
+	require.NoError(t, rt.Close())
+
+	var doc struct {
+		Log struct {
+			Entries []struct {
+				Response struct {
+					Content struct {
+						Text        string `json:"text"`
+						Encoding    string `json:"encoding"`
+						Compression int    `json:"compression"`
+					} `json:"content"`
+				} `json:"response"`
+			} `json:"entries"`
+		} `json:"log"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	require.Len(t, doc.Log.Entries, 1)
+
+	content := doc.Log.Entries[0].Response.Content
+	assert.Equal(t, "base64", content.Encoding)
+
+	decoded, err := base64.StdEncoding.DecodeString(content.Text)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(plaintext), string(decoded))
+
+	assert.NotEqual(t, 0, content.Compression)
+}
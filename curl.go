@@ -0,0 +1,147 @@
+package har
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// EntryToCurl renders entry.Request as a runnable curl command, so that a
+// captured HAR can be replayed from a shell without an external converter.
+func EntryToCurl(entry *Entry) (string, error) {
+	if entry == nil || entry.Request == nil {
+		return "", fmt.Errorf("entry has no request")
+	}
+
+	req := entry.Request
+
+	var b strings.Builder
+	b.WriteString("curl -X ")
+	b.WriteString(shellQuote(req.Method))
+
+	for _, h := range req.Headers {
+		b.WriteString(" -H ")
+		b.WriteString(shellQuote(fmt.Sprintf("%s: %s", h.Name, h.Value)))
+	}
+
+	if len(req.Cookies) > 0 {
+		pairs := make([]string, 0, len(req.Cookies))
+		for _, c := range req.Cookies {
+			pairs = append(pairs, fmt.Sprintf("%s=%s", c.Name, c.Value))
+		}
+		b.WriteString(" -b ")
+		b.WriteString(shellQuote(strings.Join(pairs, "; ")))
+	}
+
+	flag, base64Body, err := curlBodyFlag(req.PostData)
+	if err != nil {
+		return "", err
+	}
+
+	if flag != "" {
+		b.WriteString(" ")
+		b.WriteString(flag)
+	}
+
+	b.WriteString(" ")
+	b.WriteString(shellQuote(req.URL))
+
+	if base64Body == "" {
+		return b.String(), nil
+	}
+
+	// Binary bodies can't survive as a shell argument, so pipe a base64
+	// heredoc through `base64 -d` into curl's stdin instead.
+	return fmt.Sprintf("base64 -d <<'EOF' | %s\n%s\nEOF", b.String(), base64Body), nil
+}
+
+// curlBodyFlag renders the --data-raw/--data-binary/-F flags for a request
+// body. Multipart bodies are reconstructed as repeated -F flags; text bodies
+// are inlined with --data-raw; binary bodies use --data-binary @- and return
+// their base64 payload separately so the caller can wrap the command in a
+// decoding heredoc.
+func curlBodyFlag(postData *PostData) (flag string, base64Body string, err error) {
+	if postData == nil {
+		return "", "", nil
+	}
+
+	if strings.HasPrefix(postData.MimeType, "multipart/form-data") {
+		var b strings.Builder
+		for i, param := range postData.Params {
+			if i > 0 {
+				b.WriteString(" ")
+			}
+
+			b.WriteString("-F ")
+			if param.FileName != "" {
+				value := fmt.Sprintf("%s=@%s", param.Name, param.FileName)
+				if param.ContentType != "" {
+					value += ";type=" + param.ContentType
+				}
+				b.WriteString(shellQuote(value))
+			} else {
+				b.WriteString(shellQuote(fmt.Sprintf("%s=%s", param.Name, param.Value)))
+			}
+		}
+
+		return b.String(), "", nil
+	}
+
+	if postData.Text == "" {
+		return "", "", nil
+	}
+
+	if isPrintableText(postData.Text) {
+		return "--data-raw " + shellQuote(postData.Text), "", nil
+	}
+
+	return "--data-binary @-", base64.StdEncoding.EncodeToString([]byte(postData.Text)), nil
+}
+
+// isPrintableText is a best-effort check for whether a body is safe to pass
+// to --data-raw as-is, rather than routing it through a base64 heredoc.
+func isPrintableText(s string) bool {
+	for _, r := range s {
+		if r == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// injectCurlField appends a "_curl" field holding entry's curl command to an
+// already-marshaled entry, the same way Options.Rewrite patches entries: by
+// splicing raw JSON rather than re-marshaling the whole struct. If the curl
+// command can't be built, entryJson is returned unchanged.
+func injectCurlField(entryJson json.RawMessage, entry *Entry) json.RawMessage {
+	curl, err := EntryToCurl(entry)
+	if err != nil {
+		return entryJson
+	}
+
+	field, err := json.Marshal(curl)
+	if err != nil {
+		return entryJson
+	}
+
+	trimmed := bytes.TrimRight(entryJson, " \t\r\n")
+	trimmed = bytes.TrimSuffix(trimmed, []byte("}"))
+
+	patched := bytes.Buffer{}
+	patched.Write(trimmed)
+	patched.WriteString(`,"_curl":`)
+	patched.Write(field)
+	patched.WriteByte('}')
+
+	return patched.Bytes()
+}
+
+// shellQuote POSIX single-quote escapes s: each embedded ' is closed out,
+// escaped, and reopened, which is the only fully general way to quote
+// arbitrary bytes for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
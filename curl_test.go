@@ -0,0 +1,36 @@
+package har_test
+
+import (
+	"testing"
+
+	"github.com/aidansteele/har"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntryToCurl(t *testing.T) {
+	entry := &har.Entry{
+		Request: &har.Request{
+			Method: "POST",
+			URL:    "https://example.com/login",
+			Headers: []*har.NVP{
+				{Name: "Content-Type", Value: "application/x-www-form-urlencoded"},
+			},
+			Cookies: []*har.Cookie{
+				{Name: "session", Value: "abc'123"},
+			},
+			PostData: &har.PostData{
+				MimeType: "application/x-www-form-urlencoded",
+				Text:     "user=alice&pass=it's-a-secret",
+			},
+		},
+	}
+
+	cmd, err := har.EntryToCurl(entry)
+	require.NoError(t, err)
+
+	assert.Contains(t, cmd, "curl -X 'POST'")
+	assert.Contains(t, cmd, "-b 'session=abc'\\''123'")
+	assert.Contains(t, cmd, "--data-raw 'user=alice&pass=it'\\''s-a-secret'")
+	assert.Contains(t, cmd, "'https://example.com/login'")
+}
@@ -0,0 +1,276 @@
+package har
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Handler wraps next so that every request/response pair it serves is
+// recorded as a HAR entry and written to w. It is the server-side sibling of
+// RoundTripper: the two can be composed in a single process (e.g. a proxy)
+// to capture both legs of the traffic into the same sink.
+//
+// Close must be called once the handler is done serving requests to write
+// the closing bracket of the HAR document; the returned http.Handler also
+// implements io.Closer and PageStarter, matching RoundTripper.StartPage and
+// HarWriter.StartPage; pages are only written out when opts.Pages is set.
+func Handler(next http.Handler, w io.Writer, opts *Options) http.Handler {
+	if opts == nil {
+		opts = DefaultOptions
+	}
+
+	if opts.Rewrite == nil {
+		opts.Rewrite = DefaultOptions.Rewrite
+	}
+
+	if opts.Creator == nil {
+		opts.Creator = DefaultOptions.Creator
+	}
+
+	return &harHandler{
+		next:         next,
+		opts:         opts,
+		writer:       w,
+		first:        true,
+		pagesEnabled: opts.Pages,
+	}
+}
+
+// StartPage begins a new page grouping for subsequent entries whose request
+// context carries WithPage(ctx, id). It only makes sense when the handler
+// was constructed with Options.Pages set.
+func (h *harHandler) StartPage(id, title string) *Page {
+	page := &Page{ID: id, Title: title, StartedDateTime: Time(time.Now())}
+
+	h.mut.Lock()
+	h.pages = append(h.pages, page)
+	h.mut.Unlock()
+
+	return page
+}
+
+var _ http.Handler = (*harHandler)(nil)
+var _ io.Closer = (*harHandler)(nil)
+
+type harHandler struct {
+	next         http.Handler
+	opts         *Options
+	writer       io.Writer
+	preambleOnce sync.Once
+	preambleErr  error
+	mut          sync.Mutex
+	first        bool
+
+	// pagesEnabled mirrors HarWriter's buffering mode: when set, entries are
+	// held in memory until Close so the "pages" array can be written ahead
+	// of "entries". See StartPage.
+	pagesEnabled bool
+	pages        []*Page
+	buffered     []json.RawMessage
+}
+
+func (h *harHandler) writePreamble() error {
+	var err error
+	creatorJson, _ := json.Marshal(h.opts.Creator)
+
+	_, err = h.writer.Write([]byte(`{"log":{"version":"1.2","creator":`))
+	if err != nil {
+		return fmt.Errorf("writing preamble: %w", err)
+	}
+
+	_, err = h.writer.Write(creatorJson)
+	if err != nil {
+		return fmt.Errorf("writing preamble: %w", err)
+	}
+
+	_, err = h.writer.Write([]byte(`,"entries":[` + "\n"))
+	if err != nil {
+		return fmt.Errorf("writing preamble: %w", err)
+	}
+
+	return nil
+}
+
+func (h *harHandler) Close() error {
+	if h.pagesEnabled {
+		return writeBufferedLog(h.writer, h.opts.Creator, h.pages, h.buffered)
+	}
+
+	_, err := h.writer.Write([]byte("\n]}}"))
+	if err != nil {
+		return fmt.Errorf("closing har writer: %w", err)
+	}
+
+	return nil
+}
+
+func (h *harHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.pagesEnabled {
+		h.preambleOnce.Do(func() {
+			h.preambleErr = h.writePreamble()
+		})
+	}
+
+	if h.preambleErr != nil {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	entry := &Entry{}
+	req, err := buildHARRequest(r)
+	if err != nil {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	entry.Request = req
+
+	rec := newResponseRecorder(w)
+	start := time.Now()
+	h.next.ServeHTTP(rec, r)
+	end := time.Now()
+
+	entry.Response = rec.harResponse()
+	entry.StartedDateTime = Time(start)
+	entry.Time = Duration(end.Sub(start))
+	entry.Timings = &Timings{
+		Blocked: -1,
+		DNS:     -1,
+		Connect: -1,
+		Send:    -1,
+		Wait:    Duration(rec.headerWrittenAt.Sub(start)),
+		Receive: Duration(end.Sub(rec.headerWrittenAt)),
+		SSL:     -1,
+	}
+
+	h.writeEntry(r, entry)
+}
+
+func (h *harHandler) writeEntry(r *http.Request, entry *Entry) {
+	originalSize, redacted := redactEntry(entry, h.opts.Redact)
+
+	entryJson, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	if redacted {
+		entryJson = injectOriginalSize(entryJson, originalSize)
+	}
+
+	if h.opts.EmitCurl {
+		entryJson = injectCurlField(entryJson, entry)
+	}
+
+	entryJson = injectPageRef(entryJson, pageFromContext(r.Context()))
+
+	entryJson = h.opts.Rewrite(r, nil, entryJson)
+	if entryJson == nil {
+		return
+	}
+
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	if h.pagesEnabled {
+		h.buffered = append(h.buffered, entryJson)
+		return
+	}
+
+	if !h.first {
+		_, err = h.writer.Write([]byte(",\n"))
+		if err != nil {
+			return
+		}
+	}
+
+	h.first = false
+	_, _ = h.writer.Write(entryJson)
+}
+
+// responseRecorder captures a handler's response the way
+// httptest.ResponseRecorder does, but streams the real response through to
+// the underlying http.ResponseWriter as it's written.
+type responseRecorder struct {
+	http.ResponseWriter
+	status          int
+	headerWritten   bool
+	headerWrittenAt time.Time
+	body            bytes.Buffer
+}
+
+func newResponseRecorder(w http.ResponseWriter) *responseRecorder {
+	return &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.headerWritten {
+		r.status = status
+		r.headerWritten = true
+		r.headerWrittenAt = time.Now()
+	}
+
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if !r.headerWritten {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) harResponse() *Response {
+	if !r.headerWritten {
+		r.headerWrittenAt = time.Now()
+	}
+
+	header := r.Header()
+	mimeType := header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(mimeType)
+	compressedBody := r.body.Bytes()
+	compressedSize := int64(len(compressedBody))
+
+	decompressed, err := decompressBody(header.Get("Content-Encoding"), compressedBody)
+	if err != nil {
+		// The handler already wrote whatever it wrote; don't fail the
+		// capture over a body we can't decompress.
+		decompressed = compressedBody
+	}
+
+	text, encoding := encodeBody(mediaType, decompressed)
+	decompressedSize := int64(len(decompressed))
+	statusLine := fmt.Sprintf("HTTP/1.1 %d %s", r.status, http.StatusText(r.status))
+
+	return &Response{
+		Status:      r.status,
+		StatusText:  http.StatusText(r.status),
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     toHARCookies(readSetCookies(header)),
+		Headers:     toHARNVP(header),
+		RedirectURL: header.Get("Location"),
+		HeadersSize: headerBlockSize(statusLine, header),
+		BodySize:    compressedSize,
+		Content: &Content{
+			Size:        decompressedSize,
+			Compression: decompressedSize - compressedSize,
+			MimeType:    mimeType,
+			Text:        text,
+			Encoding:    encoding,
+		},
+	}
+}
+
+// readSetCookies parses the Set-Cookie headers written by the handler into
+// the same *http.Cookie shape toHARCookies already knows how to convert.
+func readSetCookies(header http.Header) []*http.Cookie {
+	resp := http.Response{Header: header}
+	return resp.Cookies()
+}
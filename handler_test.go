@@ -0,0 +1,122 @@
+package har_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aidansteele/har"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_CapturesEntry(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	})
+
+	buf := &bytes.Buffer{}
+	h := har.Handler(next, buf, nil)
+
+	req := httptest.NewRequest("POST", "/widgets", bytes.NewBufferString(`{"name":"gadget"}`))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, `{"ok":true}`, rec.Body.String())
+
+	closer, ok := h.(io.Closer)
+	require.True(t, ok)
+	require.NoError(t, closer.Close())
+
+	var doc struct {
+		Log struct {
+			Entries []struct {
+				Request struct {
+					Method string `json:"method"`
+					URL    string `json:"url"`
+				} `json:"request"`
+				Response struct {
+					Status  int `json:"status"`
+					Content struct {
+						Text     string `json:"text"`
+						Encoding string `json:"encoding"`
+					} `json:"content"`
+				} `json:"response"`
+			} `json:"entries"`
+		} `json:"log"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	require.Len(t, doc.Log.Entries, 1)
+
+	entry := doc.Log.Entries[0]
+	assert.Equal(t, "POST", entry.Request.Method)
+	assert.Equal(t, http.StatusCreated, entry.Response.Status)
+
+	// application/json isn't text/*, so encodeBody stores it base64-encoded.
+	require.Equal(t, "base64", entry.Response.Content.Encoding)
+	decoded, err := base64.StdEncoding.DecodeString(entry.Response.Content.Text)
+	require.NoError(t, err)
+	assert.Equal(t, `{"ok":true}`, string(decoded))
+}
+
+func TestHandler_PagesAndRedact(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"password":"hunter2","username":"alice"}`))
+	})
+
+	buf := &bytes.Buffer{}
+	h := har.Handler(next, buf, &har.Options{
+		Pages: true,
+		Redact: &har.Redact{
+			JSONFields: []string{"password"},
+		},
+	})
+
+	starter, ok := h.(har.PageStarter)
+	require.True(t, ok)
+	starter.StartPage("page_1", "widgets")
+
+	req := httptest.NewRequest("GET", "/widgets", nil)
+	req = req.WithContext(har.WithPage(req.Context(), "page_1"))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	closer, ok := h.(io.Closer)
+	require.True(t, ok)
+	require.NoError(t, closer.Close())
+
+	out := buf.String()
+	assert.Contains(t, out, `"pages":[`)
+	assert.Contains(t, out, `"pageref":"page_1"`)
+
+	var doc struct {
+		Log struct {
+			Entries []struct {
+				Response struct {
+					Content struct {
+						Text     string `json:"text"`
+						Encoding string `json:"encoding"`
+					} `json:"content"`
+				} `json:"response"`
+			} `json:"entries"`
+		} `json:"log"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	require.Len(t, doc.Log.Entries, 1)
+
+	content := doc.Log.Entries[0].Response.Content
+	require.Equal(t, "base64", content.Encoding)
+
+	decoded, err := base64.StdEncoding.DecodeString(content.Text)
+	require.NoError(t, err)
+	assert.NotContains(t, string(decoded), "hunter2")
+	assert.Contains(t, string(decoded), "[REDACTED]")
+}
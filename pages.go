@@ -0,0 +1,169 @@
+package har
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Page is a handle to a single entry in a HAR document's top-level "pages"
+// array, as returned by HarWriter.StartPage / RoundTripper.StartPage. It
+// lets callers record the onContentLoad/onLoad timings the HAR 1.2 spec
+// defines, which Chrome DevTools uses to group requests by page.
+type Page struct {
+	ID              string
+	Title           string
+	StartedDateTime Time
+
+	mut     sync.Mutex
+	timings PageTimings
+}
+
+// PageTimings mirrors the HAR spec's pageTimings object.
+type PageTimings struct {
+	OnContentLoad Duration `json:"onContentLoad"`
+	OnLoad        Duration `json:"onLoad"`
+}
+
+// OnContentLoad records the page's onContentLoad timing as the elapsed time
+// since the page was started.
+func (p *Page) OnContentLoad() {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	p.timings.OnContentLoad = Duration(time.Since(time.Time(p.StartedDateTime)))
+}
+
+// OnLoad records the page's onLoad timing as the elapsed time since the page
+// was started.
+func (p *Page) OnLoad() {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	p.timings.OnLoad = Duration(time.Since(time.Time(p.StartedDateTime)))
+}
+
+func (p *Page) marshalJSON() ([]byte, error) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	return json.Marshal(struct {
+		ID              string      `json:"id"`
+		Title           string      `json:"title"`
+		StartedDateTime Time        `json:"startedDateTime"`
+		PageTimings     PageTimings `json:"pageTimings"`
+	}{
+		ID:              p.ID,
+		Title:           p.Title,
+		StartedDateTime: p.StartedDateTime,
+		PageTimings:     p.timings,
+	})
+}
+
+// PageStarter is implemented by every sink that supports page grouping
+// (HarWriter, RoundTripper, and the handler returned by Handler) so that
+// callers who only hold the narrower Writer/http.Handler/io.Closer
+// interfaces can still reach StartPage via a type assertion.
+type PageStarter interface {
+	StartPage(id, title string) *Page
+}
+
+var (
+	_ PageStarter = (*HarWriter)(nil)
+	_ PageStarter = (*RoundTripper)(nil)
+	_ PageStarter = (*harHandler)(nil)
+)
+
+type pageContextKey struct{}
+
+// WithPage returns a copy of ctx that associates outgoing/incoming HAR
+// entries with the page identified by pageID. RoundTripper and Handler read
+// this back to set the entry's "pageref" so that Chrome DevTools (and any
+// other HAR 1.2 consumer) can group requests by page.
+func WithPage(ctx context.Context, pageID string) context.Context {
+	return context.WithValue(ctx, pageContextKey{}, pageID)
+}
+
+func pageFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(pageContextKey{}).(string)
+	return id
+}
+
+// injectPageRef splices a "pageref" field into an already-marshaled entry,
+// the same way injectCurlField splices in "_curl".
+func injectPageRef(entryJson json.RawMessage, pageID string) json.RawMessage {
+	if pageID == "" {
+		return entryJson
+	}
+
+	field, err := json.Marshal(pageID)
+	if err != nil {
+		return entryJson
+	}
+
+	trimmed := bytes.TrimRight(entryJson, " \t\r\n")
+	trimmed = bytes.TrimSuffix(trimmed, []byte("}"))
+
+	return append(append(append(trimmed, []byte(`,"pageref":`)...), field...), '}')
+}
+
+// writeBufferedLog writes a complete HAR document in one shot: preamble,
+// "pages" (if any), then "entries". It's used instead of the usual
+// streaming preamble/Close pair whenever pages are in use, since the pages
+// array must be known up front and HAR readers expect it ahead of entries.
+func writeBufferedLog(w io.Writer, creator *Creator, pages []*Page, entries []json.RawMessage) error {
+	creatorJson, _ := json.Marshal(creator)
+
+	if _, err := w.Write([]byte(`{"log":{"version":"1.2","creator":`)); err != nil {
+		return fmt.Errorf("writing preamble: %w", err)
+	}
+
+	if _, err := w.Write(creatorJson); err != nil {
+		return fmt.Errorf("writing preamble: %w", err)
+	}
+
+	if _, err := w.Write([]byte(`,"pages":[`)); err != nil {
+		return fmt.Errorf("writing pages: %w", err)
+	}
+
+	for i, page := range pages {
+		if i > 0 {
+			if _, err := w.Write([]byte(",")); err != nil {
+				return fmt.Errorf("writing pages: %w", err)
+			}
+		}
+
+		pageJson, err := page.marshalJSON()
+		if err != nil {
+			return fmt.Errorf("marshaling page %q: %w", page.ID, err)
+		}
+
+		if _, err := w.Write(pageJson); err != nil {
+			return fmt.Errorf("writing pages: %w", err)
+		}
+	}
+
+	if _, err := w.Write([]byte(`],"entries":[` + "\n")); err != nil {
+		return fmt.Errorf("writing entries: %w", err)
+	}
+
+	for i, entry := range entries {
+		if i > 0 {
+			if _, err := w.Write([]byte(",\n")); err != nil {
+				return fmt.Errorf("writing entries: %w", err)
+			}
+		}
+
+		if _, err := w.Write(entry); err != nil {
+			return fmt.Errorf("writing entries: %w", err)
+		}
+	}
+
+	if _, err := w.Write([]byte("\n]}}")); err != nil {
+		return fmt.Errorf("closing har document: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package har_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aidansteele/har"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTripper_Pages(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	buf := &bytes.Buffer{}
+	rt, err := har.New(nil, buf, &har.Options{Pages: true})
+	require.NoError(t, err)
+
+	page := rt.StartPage("page_1", "login")
+
+	c := &http.Client{Transport: rt}
+	req, _ := http.NewRequest("GET", upstream.URL, nil)
+	req = req.WithContext(har.WithPage(req.Context(), "page_1"))
+
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+	assert.NotNil(t, resp)
+
+	page.OnContentLoad()
+	page.OnLoad()
+
+	require.NoError(t, rt.Close())
+
+	out := buf.String()
+	assert.Contains(t, out, `"pages":[`)
+	assert.Contains(t, out, `"id":"page_1"`)
+	assert.Contains(t, out, `"pageref":"page_1"`)
+}
@@ -0,0 +1,131 @@
+package har
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Reader streams HAR entries one at a time so that multi-gigabyte captures
+// don't need to be held in memory all at once. It supports both the
+// standard `{"log":{"entries":[...]}}` document produced by HarWriter and
+// the newline-delimited entries produced by HarNDWriter.
+type Reader struct {
+	dec  *json.Decoder
+	nd   *bufio.Scanner
+	done bool
+}
+
+// NewReader inspects the start of r to decide which of the two formats it's
+// reading, then returns a Reader positioned to yield entries via Next.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReaderSize(r, 64*1024)
+
+	// Both formats start with '{' - a lone entry (ND) is itself a JSON
+	// object, same as the {"log":{...}} container - so the first byte
+	// can't tell them apart. Peek far enough to read the first key instead.
+	peeked, err := br.Peek(4096)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("peeking har document: %w", err)
+	}
+
+	if isContainerDocument(peeked) {
+		return newContainerReader(br)
+	}
+
+	scanner := bufio.NewScanner(br)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	return &Reader{nd: scanner}, nil
+}
+
+// isContainerDocument reports whether b looks like the start of a
+// {"log":{...}} document, as opposed to a bare entry object (what an ND
+// document's lines, and a lone unwrapped entry, both are).
+func isContainerDocument(b []byte) bool {
+	dec := json.NewDecoder(bytes.NewReader(b))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return false
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return false
+	}
+
+	tok, err = dec.Token()
+	if err != nil {
+		return false
+	}
+
+	name, ok := tok.(string)
+	return ok && name == "log"
+}
+
+// newContainerReader walks the `{"log":{...}}` tokens until it reaches the
+// "entries" array, then leaves the decoder positioned to stream its
+// elements one at a time via json.Decoder.Decode.
+func newContainerReader(r io.Reader) (*Reader, error) {
+	dec := json.NewDecoder(r)
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("scanning har document for entries: %w", err)
+		}
+
+		if name, ok := tok.(string); ok && name == "entries" {
+			break
+		}
+	}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf("reading entries array: %w", err)
+	}
+
+	if d, ok := tok.(json.Delim); !ok || d != '[' {
+		return nil, fmt.Errorf("expected entries to be an array, got %v", tok)
+	}
+
+	return &Reader{dec: dec}, nil
+}
+
+// Next returns the next entry in the capture, or (nil, io.EOF) once
+// exhausted.
+func (r *Reader) Next() (*Entry, error) {
+	if r.done {
+		return nil, io.EOF
+	}
+
+	if r.dec != nil {
+		if !r.dec.More() {
+			r.done = true
+			return nil, io.EOF
+		}
+
+		entry := &Entry{}
+		if err := r.dec.Decode(entry); err != nil {
+			return nil, fmt.Errorf("decoding har entry: %w", err)
+		}
+
+		return entry, nil
+	}
+
+	if !r.nd.Scan() {
+		r.done = true
+		if err := r.nd.Err(); err != nil {
+			return nil, fmt.Errorf("scanning har entry: %w", err)
+		}
+
+		return nil, io.EOF
+	}
+
+	entry := &Entry{}
+	if err := json.Unmarshal(r.nd.Bytes(), entry); err != nil {
+		return nil, fmt.Errorf("decoding har entry: %w", err)
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,91 @@
+package har_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/aidansteele/har"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReader_Container(t *testing.T) {
+	entries := []*har.Entry{
+		{Request: &har.Request{Method: "GET", URL: "https://example.com/a"}},
+		{Request: &har.Request{Method: "POST", URL: "https://example.com/b"}},
+	}
+
+	r := buildContainerDocument(t, entries)
+	reader, err := har.NewReader(r)
+	require.NoError(t, err)
+
+	assertReadsAllEntries(t, reader, entries)
+}
+
+func TestReader_NewlineDelimited(t *testing.T) {
+	entries := []*har.Entry{
+		{Request: &har.Request{Method: "GET", URL: "https://example.com/a"}},
+		{Request: &har.Request{Method: "POST", URL: "https://example.com/b"}},
+	}
+
+	r := buildNDDocument(t, entries)
+	reader, err := har.NewReader(r)
+	require.NoError(t, err)
+
+	assertReadsAllEntries(t, reader, entries)
+}
+
+func assertReadsAllEntries(t *testing.T, reader *har.Reader, want []*har.Entry) {
+	t.Helper()
+
+	for i, w := range want {
+		got, err := reader.Next()
+		require.NoError(t, err, "entry %d", i)
+		require.Equal(t, w.Request.Method, got.Request.Method, "entry %d", i)
+		require.Equal(t, w.Request.URL, got.Request.URL, "entry %d", i)
+	}
+
+	_, err := reader.Next()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func buildContainerDocument(t *testing.T, entries []*har.Entry) io.Reader {
+	t.Helper()
+
+	creatorJson, err := json.Marshal(&har.Creator{Name: "reader_test", Version: "0.1"})
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(`{"log":{"version":"1.2","creator":`)
+	buf.Write(creatorJson)
+	buf.WriteString(`,"entries":[`)
+
+	for i, entry := range entries {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+
+		entryJson, err := json.Marshal(entry)
+		require.NoError(t, err)
+		buf.Write(entryJson)
+	}
+
+	buf.WriteString(`]}}`)
+
+	return buf
+}
+
+func buildNDDocument(t *testing.T, entries []*har.Entry) io.Reader {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	for _, entry := range entries {
+		entryJson, err := json.Marshal(entry)
+		require.NoError(t, err)
+		fmt.Fprintf(buf, "%s\n", entryJson)
+	}
+
+	return buf
+}
@@ -0,0 +1,384 @@
+package har
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const defaultRedactPlaceholder = "[REDACTED]"
+
+// RedactRule matches a header/cookie/query-param/form-field name by either
+// an exact, case-insensitive string or a regular expression. Exactly one of
+// Name or Pattern should be set.
+type RedactRule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+func (r RedactRule) matches(name string) bool {
+	if r.Pattern != nil {
+		return r.Pattern.MatchString(name)
+	}
+
+	return strings.EqualFold(r.Name, name)
+}
+
+func matchesAnyRule(rules []RedactRule, name string) bool {
+	for _, rule := range rules {
+		if rule.matches(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Redact declares what should be scrubbed from a captured entry before it's
+// written out. JSONFields uses dot-separated selectors (e.g. "password",
+// "data.*.ssn") where "*" matches every key of an object or every element of
+// an array at that position in the path.
+type Redact struct {
+	Headers     []RedactRule
+	Cookies     []RedactRule
+	QueryParams []RedactRule
+	FormFields  []RedactRule
+	JSONFields  []string
+	Placeholder string
+}
+
+func (r *Redact) placeholder() string {
+	if r == nil || r.Placeholder == "" {
+		return defaultRedactPlaceholder
+	}
+
+	return r.Placeholder
+}
+
+// DefaultSensitiveHeaders lists header names that commonly carry
+// credentials, as a starting point for Redact.Headers.
+func DefaultSensitiveHeaders() []string {
+	return []string{
+		"Authorization",
+		"Cookie",
+		"Set-Cookie",
+		"Proxy-Authorization",
+		"X-Api-Key",
+		"X-Auth-Token",
+		"X-Csrf-Token",
+	}
+}
+
+// redactEntry scrubs entry in place per cfg, before it's marshaled, so that
+// both the JSON written out and any later consumer of the struct (e.g.
+// EntryToCurl for Options.EmitCurl) only ever see redacted data. It returns
+// the pre-redaction byte length of the response content, if any was
+// redacted, so the caller can record it as the "_originalSize" extension.
+func redactEntry(entry *Entry, cfg *Redact) (responseOriginalSize int, redacted bool) {
+	if cfg == nil {
+		return 0, false
+	}
+
+	placeholder := cfg.placeholder()
+
+	if entry.Request != nil {
+		redactNVPs(entry.Request.Headers, cfg.Headers, placeholder)
+		redactCookies(entry.Request.Cookies, cfg.Cookies, placeholder)
+		redactNVPs(entry.Request.QueryString, cfg.QueryParams, placeholder)
+		if u, err := url.Parse(entry.Request.URL); err == nil && u.RawQuery != "" {
+			query, changed := u.Query(), false
+			for key := range query {
+				if matchesAnyRule(cfg.QueryParams, key) {
+					query.Set(key, placeholder)
+					changed = true
+				}
+			}
+			if changed {
+				u.RawQuery = query.Encode()
+				entry.Request.URL = u.String()
+			}
+		}
+		redactPostData(entry.Request.PostData, cfg)
+	}
+
+	if entry.Response != nil {
+		redactNVPs(entry.Response.Headers, cfg.Headers, placeholder)
+		redactCookies(entry.Response.Cookies, cfg.Cookies, placeholder)
+
+		if content := entry.Response.Content; content != nil {
+			if size, ok := redactContent(content, cfg); ok {
+				responseOriginalSize, redacted = size, true
+			}
+		}
+	}
+
+	return responseOriginalSize, redacted
+}
+
+func redactNVPs(nvps []*NVP, rules []RedactRule, placeholder string) {
+	for _, nvp := range nvps {
+		if matchesAnyRule(rules, nvp.Name) {
+			nvp.Value = placeholder
+		}
+	}
+}
+
+func redactCookies(cookies []*Cookie, rules []RedactRule, placeholder string) {
+	for _, c := range cookies {
+		if matchesAnyRule(rules, c.Name) {
+			c.Value = placeholder
+		}
+	}
+}
+
+func redactPostData(postData *PostData, cfg *Redact) {
+	if postData == nil {
+		return
+	}
+
+	placeholder := cfg.placeholder()
+	for _, param := range postData.Params {
+		if matchesAnyRule(cfg.FormFields, param.Name) {
+			param.Value = placeholder
+		}
+	}
+
+	if postData.Text == "" {
+		return
+	}
+
+	switch {
+	case strings.Contains(postData.MimeType, "json"):
+		postData.Text = redactJSONText(postData.Text, cfg.JSONFields, placeholder)
+
+	case strings.HasPrefix(postData.MimeType, "application/x-www-form-urlencoded"):
+		if values, err := url.ParseQuery(postData.Text); err == nil {
+			for key := range values {
+				if matchesAnyRule(cfg.FormFields, key) {
+					values.Set(key, placeholder)
+				}
+			}
+			postData.Text = values.Encode()
+		}
+
+	case strings.HasPrefix(postData.MimeType, "multipart/form-data"):
+		if redactedText, newMimeType, ok := redactMultipartText(postData.Text, postData.MimeType, cfg.FormFields, placeholder); ok {
+			postData.Text = redactedText
+			postData.MimeType = newMimeType
+		}
+	}
+}
+
+// redactMultipartText re-encodes a multipart/form-data body with any field
+// whose name matches rules replaced by placeholder. File parts are copied
+// through unchanged. Since rebuilding the body picks a new boundary (the
+// original isn't recoverable from the parsed parts), it returns the
+// MimeType that must replace postData.MimeType alongside the new text; ok
+// is false if text couldn't be parsed as the boundary mimeType declares, in
+// which case the caller should leave postData untouched.
+func redactMultipartText(text, mimeType string, rules []RedactRule, placeholder string) (newText, newMimeType string, ok bool) {
+	_, params, err := mime.ParseMediaType(mimeType)
+	if err != nil || params["boundary"] == "" {
+		return "", "", false
+	}
+
+	mr := multipart.NewReader(strings.NewReader(text), params["boundary"])
+
+	buf := &bytes.Buffer{}
+	mw := multipart.NewWriter(buf)
+
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", "", false
+		}
+
+		value, err := io.ReadAll(part)
+		if err != nil {
+			return "", "", false
+		}
+
+		if part.FileName() != "" {
+			fw, err := mw.CreateFormFile(part.FormName(), part.FileName())
+			if err != nil {
+				return "", "", false
+			}
+			if _, err := fw.Write(value); err != nil {
+				return "", "", false
+			}
+			continue
+		}
+
+		if matchesAnyRule(rules, part.FormName()) {
+			value = []byte(placeholder)
+		}
+
+		if err := mw.WriteField(part.FormName(), string(value)); err != nil {
+			return "", "", false
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return "", "", false
+	}
+
+	return buf.String(), mw.FormDataContentType(), true
+}
+
+// redactContent redacts a response body's JSON fields in place. It returns
+// the pre-redaction byte length and true when a redaction actually
+// happened, so the caller can preserve it via the "_originalSize"
+// extension; content.Size is left as it was (the decompressed,
+// pre-redaction body length).
+func redactContent(content *Content, cfg *Redact) (originalSize int, redacted bool) {
+	if !strings.Contains(content.MimeType, "json") || content.Text == "" {
+		return 0, false
+	}
+
+	text := content.Text
+	if content.Encoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(text)
+		if err != nil {
+			return 0, false
+		}
+		text = string(decoded)
+	}
+
+	originalSize = len(text)
+	redactedText := redactJSONText(text, cfg.JSONFields, cfg.placeholder())
+	if redactedText == text {
+		return 0, false
+	}
+	text = redactedText
+
+	if content.Encoding == "base64" {
+		content.Text = base64.StdEncoding.EncodeToString([]byte(text))
+	} else {
+		content.Text = text
+	}
+
+	return originalSize, true
+}
+
+// redactJSONText rewrites the fields of a JSON document matched by
+// selectors to placeholder without round-tripping through a typed struct.
+func redactJSONText(text string, selectors []string, placeholder string) string {
+	if len(selectors) == 0 {
+		return text
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal([]byte(text), &doc); err != nil {
+		return text
+	}
+
+	for _, selector := range selectors {
+		doc = redactJSONPath(doc, strings.Split(selector, "."), placeholder)
+	}
+
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return text
+	}
+
+	return string(out)
+}
+
+// redactJSONPath walks doc along path, replacing matching leaves with
+// placeholder. "*" matches every key of an object or every element of an
+// array at that position in the path.
+func redactJSONPath(doc interface{}, path []string, placeholder string) interface{} {
+	if len(path) == 0 {
+		return placeholder
+	}
+
+	head, rest := path[0], path[1:]
+
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		if head == "*" {
+			for k, child := range v {
+				v[k] = redactJSONPath(child, rest, placeholder)
+			}
+			return v
+		}
+
+		if child, ok := v[head]; ok {
+			v[head] = redactJSONPath(child, rest, placeholder)
+		}
+
+		return v
+
+	case []interface{}:
+		if head == "*" {
+			for i, child := range v {
+				v[i] = redactJSONPath(child, rest, placeholder)
+			}
+			return v
+		}
+
+		if idx, err := strconv.Atoi(head); err == nil && idx >= 0 && idx < len(v) {
+			v[idx] = redactJSONPath(v[idx], rest, placeholder)
+		}
+
+		return v
+
+	default:
+		return doc
+	}
+}
+
+// injectOriginalSize splices a "_originalSize" field into the response.content
+// object of an already-marshaled entry, mirroring how injectCurlField
+// splices in "_curl".
+func injectOriginalSize(entryJson json.RawMessage, size int) json.RawMessage {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(entryJson, &doc); err != nil {
+		return entryJson
+	}
+
+	response, ok := doc["response"]
+	if !ok {
+		return entryJson
+	}
+
+	var respFields map[string]json.RawMessage
+	if err := json.Unmarshal(response, &respFields); err != nil {
+		return entryJson
+	}
+
+	content, ok := respFields["content"]
+	if !ok {
+		return entryJson
+	}
+
+	trimmed := bytes.TrimRight(content, " \t\r\n")
+	trimmed = bytes.TrimSuffix(trimmed, []byte("}"))
+
+	patchedContent := fmt.Sprintf(`%s,"_originalSize":%d}`, trimmed, size)
+	respFields["content"] = json.RawMessage(patchedContent)
+
+	patchedResponse, err := json.Marshal(respFields)
+	if err != nil {
+		return entryJson
+	}
+	doc["response"] = patchedResponse
+
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return entryJson
+	}
+
+	return out
+}
@@ -0,0 +1,207 @@
+package har
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Replayer reconstructs *http.Request values from captured HAR entries so
+// that recorded traffic can be re-issued through any http.RoundTripper.
+type Replayer struct{}
+
+// NewReplayer returns a Replayer. It holds no state today but is a struct,
+// rather than a bare function, so that replay options can be added later
+// without breaking callers.
+func NewReplayer() *Replayer {
+	return &Replayer{}
+}
+
+// Replay turns entry.Request back into an *http.Request. The body is
+// reconstructed from PostData: Text is used verbatim when present, falling
+// back to re-encoding Params for forms that were decomposed into fields at
+// capture time.
+func (p *Replayer) Replay(entry *Entry) (*http.Request, error) {
+	if entry == nil || entry.Request == nil {
+		return nil, fmt.Errorf("entry has no request")
+	}
+
+	req := entry.Request
+
+	body, contentType, err := p.buildBody(req.PostData)
+	if err != nil {
+		return nil, fmt.Errorf("rebuilding request body: %w", err)
+	}
+
+	httpReq, err := http.NewRequest(req.Method, req.URL, body)
+	if err != nil {
+		return nil, fmt.Errorf("constructing request: %w", err)
+	}
+
+	for _, h := range req.Headers {
+		// Cookie is rebuilt below from req.Cookies (the structured form);
+		// copying the raw header too would duplicate it on the wire.
+		if strings.EqualFold(h.Name, "Cookie") {
+			continue
+		}
+
+		httpReq.Header.Add(h.Name, h.Value)
+	}
+
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+
+	for _, c := range req.Cookies {
+		httpReq.AddCookie(&http.Cookie{Name: c.Name, Value: c.Value})
+	}
+
+	return httpReq, nil
+}
+
+// buildBody reconstructs the request body and, for multipart bodies, the
+// Content-Type header carrying the boundary that was chosen while
+// rebuilding it (the original boundary isn't preserved in the HAR).
+func (p *Replayer) buildBody(postData *PostData) (io.Reader, string, error) {
+	if postData == nil {
+		return nil, "", nil
+	}
+
+	switch {
+	case postData.Text != "":
+		return strings.NewReader(postData.Text), postData.MimeType, nil
+
+	case strings.HasPrefix(postData.MimeType, "application/x-www-form-urlencoded"):
+		values := url.Values{}
+		for _, param := range postData.Params {
+			values.Add(param.Name, param.Value)
+		}
+		return strings.NewReader(values.Encode()), postData.MimeType, nil
+
+	case strings.HasPrefix(postData.MimeType, "multipart/form-data"):
+		buf := &bytes.Buffer{}
+		mw := multipart.NewWriter(buf)
+
+		for _, param := range postData.Params {
+			if param.FileName != "" {
+				fw, err := mw.CreateFormFile(param.Name, param.FileName)
+				if err != nil {
+					return nil, "", err
+				}
+
+				if _, err := fw.Write([]byte(param.Value)); err != nil {
+					return nil, "", err
+				}
+
+				continue
+			}
+
+			if err := mw.WriteField(param.Name, param.Value); err != nil {
+				return nil, "", err
+			}
+		}
+
+		if err := mw.Close(); err != nil {
+			return nil, "", err
+		}
+
+		return buf, mw.FormDataContentType(), nil
+
+	default:
+		return nil, postData.MimeType, nil
+	}
+}
+
+// Diff compares two entries for regression testing against recorded HARs. It
+// reports differences in request method, URL and body text, and in response
+// status and decoded body bytes; a body present on only one side counts as a
+// difference. Headers, cookies, timings and dates are not compared.
+func Diff(expected, actual *Entry) []string {
+	var diffs []string
+
+	if expected == nil || actual == nil {
+		if expected != actual {
+			diffs = append(diffs, "one of expected/actual is nil")
+		}
+		return diffs
+	}
+
+	if expected.Request != nil && actual.Request != nil {
+		if expected.Request.Method != actual.Request.Method {
+			diffs = append(diffs, fmt.Sprintf("request method: expected %q, got %q", expected.Request.Method, actual.Request.Method))
+		}
+
+		if expected.Request.URL != actual.Request.URL {
+			diffs = append(diffs, fmt.Sprintf("request URL: expected %q, got %q", expected.Request.URL, actual.Request.URL))
+		}
+
+		if diff := diffPostData(expected.Request.PostData, actual.Request.PostData); diff != "" {
+			diffs = append(diffs, diff)
+		}
+	}
+
+	if expected.Response != nil && actual.Response != nil {
+		if expected.Response.Status != actual.Response.Status {
+			diffs = append(diffs, fmt.Sprintf("response status: expected %d, got %d", expected.Response.Status, actual.Response.Status))
+		}
+
+		if diff := diffContent(expected.Response.Content, actual.Response.Content); diff != "" {
+			diffs = append(diffs, diff)
+		}
+	}
+
+	return diffs
+}
+
+func diffPostData(expected, actual *PostData) string {
+	if expected == nil || actual == nil {
+		if expected != actual {
+			return "one of expected/actual request bodies is nil"
+		}
+		return ""
+	}
+
+	if expected.Text != actual.Text {
+		return fmt.Sprintf("request body: expected %q, got %q", expected.Text, actual.Text)
+	}
+
+	return ""
+}
+
+func diffContent(expected, actual *Content) string {
+	if expected == nil || actual == nil {
+		if expected != actual {
+			return "one of expected/actual response bodies is nil"
+		}
+		return ""
+	}
+
+	expectedBytes, err := decodeContent(expected)
+	if err != nil {
+		return fmt.Sprintf("decoding expected response body: %s", err)
+	}
+
+	actualBytes, err := decodeContent(actual)
+	if err != nil {
+		return fmt.Sprintf("decoding actual response body: %s", err)
+	}
+
+	if !bytes.Equal(expectedBytes, actualBytes) {
+		return fmt.Sprintf("response body: expected %d bytes, got %d bytes", len(expectedBytes), len(actualBytes))
+	}
+
+	return ""
+}
+
+func decodeContent(c *Content) ([]byte, error) {
+	if c.Encoding == "base64" {
+		return base64.StdEncoding.DecodeString(c.Text)
+	}
+
+	return []byte(c.Text), nil
+}
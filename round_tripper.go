@@ -32,11 +32,33 @@ type RoundTripper struct {
 	mut    sync.Mutex
 	first  bool
 	writer io.Writer
+
+	// pagesEnabled mirrors HarWriter's buffering mode: when set, entries are
+	// held in memory until Close so the "pages" array can be written ahead
+	// of "entries". See StartPage.
+	pagesEnabled bool
+	pages        []*Page
+	buffered     []json.RawMessage
 }
 
 type Options struct {
 	Rewrite func(request *http.Request, response *http.Response, entry json.RawMessage) json.RawMessage
 	Creator *Creator
+
+	// EmitCurl, when true, appends a "_curl" field to every written entry
+	// containing the equivalent `curl` invocation for entry.Request. See
+	// EntryToCurl.
+	EmitCurl bool
+
+	// Redact, when set, scrubs sensitive headers/cookies/query params/body
+	// fields from every written entry before Rewrite sees it.
+	Redact *Redact
+
+	// Pages enables the optional HAR "pages" array grouping entries into
+	// pages (see RoundTripper.StartPage and WithPage). Entries are buffered
+	// in memory until Close, since the pages array must be written ahead of
+	// "entries".
+	Pages bool
 }
 
 func New(roundTripper http.RoundTripper, w io.Writer, opts *Options) (*RoundTripper, error) {
@@ -65,10 +87,17 @@ func New(roundTripper http.RoundTripper, w io.Writer, opts *Options) (*RoundTrip
 	}
 
 	rt := &RoundTripper{
-		inner:  roundTripper,
-		opts:   opts,
-		writer: w,
-		first:  true,
+		inner:        roundTripper,
+		opts:         opts,
+		writer:       w,
+		first:        true,
+		pagesEnabled: opts.Pages,
+	}
+
+	if rt.pagesEnabled {
+		// The preamble can't be written until Close, once all pages started
+		// via StartPage are known.
+		return rt, nil
 	}
 
 	err := rt.writePreamble()
@@ -79,6 +108,19 @@ func New(roundTripper http.RoundTripper, w io.Writer, opts *Options) (*RoundTrip
 	return rt, nil
 }
 
+// StartPage begins a new page grouping for subsequent entries whose request
+// context carries WithPage(ctx, id). It only makes sense when the
+// RoundTripper was constructed with Options.Pages set.
+func (rt *RoundTripper) StartPage(id, title string) *Page {
+	page := &Page{ID: id, Title: title, StartedDateTime: Time(time.Now())}
+
+	rt.mut.Lock()
+	rt.pages = append(rt.pages, page)
+	rt.mut.Unlock()
+
+	return page
+}
+
 func (rt *RoundTripper) writePreamble() error {
 	var err error
 	creatorJson, _ := json.Marshal(rt.opts.Creator)
@@ -102,6 +144,10 @@ func (rt *RoundTripper) writePreamble() error {
 }
 
 func (rt *RoundTripper) Close() error {
+	if rt.pagesEnabled {
+		return writeBufferedLog(rt.writer, rt.opts.Creator, rt.pages, rt.buffered)
+	}
+
 	_, err := rt.writer.Write([]byte("\n]}}"))
 	if err != nil {
 		return fmt.Errorf("closing har writer: %w", err)
@@ -139,8 +185,20 @@ func (rt *RoundTripper) RoundTrip(request *http.Request) (response *http.Respons
 }
 
 func (rt *RoundTripper) writeEntry(request *http.Request, response *http.Response, entry *Entry) error {
+	originalSize, redacted := redactEntry(entry, rt.opts.Redact)
+
 	entryJson, err := json.Marshal(entry)
 
+	if redacted {
+		entryJson = injectOriginalSize(entryJson, originalSize)
+	}
+
+	if rt.opts.EmitCurl {
+		entryJson = injectCurlField(entryJson, entry)
+	}
+
+	entryJson = injectPageRef(entryJson, pageFromContext(request.Context()))
+
 	entryJson = rt.opts.Rewrite(request, response, entryJson)
 	if entryJson == nil {
 		return nil
@@ -149,6 +207,11 @@ func (rt *RoundTripper) writeEntry(request *http.Request, response *http.Respons
 	rt.mut.Lock()
 	defer rt.mut.Unlock()
 
+	if rt.pagesEnabled {
+		rt.buffered = append(rt.buffered, entryJson)
+		return nil
+	}
+
 	if !rt.first {
 		_, err = rt.writer.Write([]byte(",\n"))
 		if err != nil {
@@ -167,17 +230,25 @@ func (rt *RoundTripper) writeEntry(request *http.Request, response *http.Respons
 }
 
 func (rt *RoundTripper) preRoundTrip(r *http.Request, entry *Entry) error {
+	req, err := buildHARRequest(r)
+	if err != nil {
+		return err
+	}
+
+	entry.Request = req
+	return nil
+}
+
+// buildHARRequest turns an *http.Request into its HAR representation. It is
+// shared by RoundTripper (client side) and Handler (server side), which is
+// why it tolerates requests without a GetBody func.
+func buildHARRequest(r *http.Request) (*Request, error) {
 	bodySize := -1
 	var postData *PostData
 	if r.Body != nil {
-		reqBody, err := r.GetBody()
-		if err != nil {
-			return fmt.Errorf("getting body: %w", err)
-		}
-
-		reqBodyBytes, err := io.ReadAll(reqBody)
+		reqBodyBytes, err := readAndRestoreBody(r)
 		if err != nil {
-			return fmt.Errorf("reading request body: %w", err)
+			return nil, fmt.Errorf("reading request body: %w", err)
 		}
 
 		bodySize = len(reqBodyBytes)
@@ -191,14 +262,18 @@ func (rt *RoundTripper) preRoundTrip(r *http.Request, entry *Entry) error {
 
 		mediaType, _, err := mime.ParseMediaType(mimeType)
 		if err != nil {
-			return fmt.Errorf("parsing request Content-Type: %w", err)
+			// A missing Content-Type (e.g. a server-side GET, whose Body is
+			// http.NoBody) just means there's no form to decompose.
+			if mimeType != "" {
+				return nil, fmt.Errorf("parsing request Content-Type: %w", err)
+			}
 		}
 
 		switch mediaType {
 		case "application/x-www-form-urlencoded":
 			err = r.ParseForm()
 			if err != nil {
-				return fmt.Errorf("parsing urlencoded form in request body: %w", err)
+				return nil, fmt.Errorf("parsing urlencoded form in request body: %w", err)
 			}
 			r.Body = io.NopCloser(bytes.NewBuffer(reqBodyBytes))
 
@@ -214,7 +289,7 @@ func (rt *RoundTripper) preRoundTrip(r *http.Request, entry *Entry) error {
 		case "multipart/form-data":
 			err = r.ParseMultipartForm(10 * 1024 * 1024)
 			if err != nil {
-				return fmt.Errorf("parsing multipart form in request body: %w", err)
+				return nil, fmt.Errorf("parsing multipart form in request body: %w", err)
 			}
 			r.Body = io.NopCloser(bytes.NewBuffer(reqBodyBytes))
 
@@ -238,7 +313,7 @@ func (rt *RoundTripper) preRoundTrip(r *http.Request, entry *Entry) error {
 		}
 	}
 
-	entry.Request = &Request{
+	return &Request{
 		Method:      r.Method,
 		URL:         r.URL.String(),
 		HTTPVersion: r.Proto,
@@ -246,11 +321,33 @@ func (rt *RoundTripper) preRoundTrip(r *http.Request, entry *Entry) error {
 		Headers:     toHARNVP(r.Header),
 		QueryString: toHARNVP(r.URL.Query()),
 		PostData:    postData,
-		HeadersSize: -1, // TODO
+		HeadersSize: requestHeaderBlockSize(r),
 		BodySize:    bodySize,
+	}, nil
+}
+
+// readAndRestoreBody reads the full body of r and puts it back so that it can
+// be read again downstream. Client requests created via http.NewRequest
+// usually have GetBody set, which is cheaper since it doesn't require
+// rebuffering; server requests never do, so we fall back to reading r.Body
+// directly and replacing it.
+func readAndRestoreBody(r *http.Request) ([]byte, error) {
+	if r.GetBody != nil {
+		reqBody, err := r.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("getting body: %w", err)
+		}
+
+		return io.ReadAll(reqBody)
 	}
 
-	return nil
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Body = io.NopCloser(bytes.NewBuffer(b))
+	return b, nil
 }
 
 func (rt *RoundTripper) postRoundTrip(resp *http.Response, entry *Entry, trace *clientTracer) error {
@@ -262,21 +359,24 @@ func (rt *RoundTripper) postRoundTrip(resp *http.Response, entry *Entry, trace *
 
 	resp.Body = io.NopCloser(bytes.NewBuffer(respBodyBytes))
 
+	// An empty or unparseable Content-Type (e.g. a 204, or a redirect with
+	// no body) isn't an error: there's nothing to decode either way, and
+	// the capture must never fail the request it's passively observing.
 	mimeType := resp.Header.Get("Content-Type")
-	mediaType, _, err := mime.ParseMediaType(mimeType)
+	mediaType, _, _ := mime.ParseMediaType(mimeType)
+
+	compressedSize := int64(len(respBodyBytes))
+	decompressed, err := decompressBody(resp.Header.Get("Content-Encoding"), respBodyBytes)
 	if err != nil {
-		return fmt.Errorf("parsing response Content-Type: %w", err)
+		// The capture is a passive observer: if we can't decompress the
+		// body (e.g. it's truncated, or a server lied about its encoding),
+		// fall back to the raw bytes rather than failing the request the
+		// caller is waiting on.
+		decompressed = respBodyBytes
 	}
 
-	var text string
-	var encoding string
-	switch {
-	case strings.HasPrefix(mediaType, "text/"):
-		text = string(respBodyBytes)
-	default:
-		text = base64.StdEncoding.EncodeToString(respBodyBytes)
-		encoding = "base64"
-	}
+	text, encoding := encodeBody(mediaType, decompressed)
+	decompressedSize := int64(len(decompressed))
 
 	entry.Response = &Response{
 		Status:      resp.StatusCode,
@@ -285,11 +385,11 @@ func (rt *RoundTripper) postRoundTrip(resp *http.Response, entry *Entry, trace *
 		Cookies:     toHARCookies(resp.Cookies()),
 		Headers:     toHARNVP(resp.Header),
 		RedirectURL: resp.Header.Get("Location"),
-		HeadersSize: -1,
-		BodySize:    resp.ContentLength,
+		HeadersSize: responseHeaderBlockSize(resp),
+		BodySize:    compressedSize,
 		Content: &Content{
-			Size:        resp.ContentLength, // TODO 圧縮されている場合のフォロー
-			Compression: 0,
+			Size:        decompressedSize,
+			Compression: decompressedSize - compressedSize,
 			MimeType:    mimeType,
 			Text:        text,
 			Encoding:    encoding,
@@ -322,6 +422,17 @@ func (rt *RoundTripper) postRoundTrip(resp *http.Response, entry *Entry, trace *
 	return nil
 }
 
+// encodeBody picks the HAR text/encoding representation for a response or
+// request body: text/* media types are stored as-is, everything else is
+// base64 encoded since HAR's "text" field must be valid UTF-8/JSON string.
+func encodeBody(mediaType string, body []byte) (text string, encoding string) {
+	if strings.HasPrefix(mediaType, "text/") {
+		return string(body), ""
+	}
+
+	return base64.StdEncoding.EncodeToString(body), "base64"
+}
+
 func toHARCookies(cookies []*http.Cookie) []*Cookie {
 	harCookies := make([]*Cookie, 0, len(cookies))
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"sync"
+	"time"
 )
 
 type Writer interface {
@@ -14,10 +15,18 @@ type Writer interface {
 var _ Writer = (*HarWriter)(nil)
 
 type HarWriter struct {
-	first  bool
-	closed bool
-	mut    sync.Mutex
-	writer io.Writer
+	first   bool
+	closed  bool
+	mut     sync.Mutex
+	writer  io.Writer
+	creator *Creator
+
+	// pagesEnabled buffers entries in memory instead of streaming them, so
+	// that Close can write the "pages" array ahead of "entries" once all
+	// pages are known. See StartPage.
+	pagesEnabled bool
+	pages        []*Page
+	buffered     []json.RawMessage
 }
 
 func NewHarWriter(writer io.Writer, creator *Creator) (*HarWriter, error) {
@@ -45,6 +54,33 @@ func NewHarWriter(writer io.Writer, creator *Creator) (*HarWriter, error) {
 	}, nil
 }
 
+// NewHarWriterWithPages is like NewHarWriter but enables the optional HAR
+// "pages" array grouping entries into pages (see StartPage). Because the
+// pages array must be written ahead of "entries", entries are buffered in
+// memory until Close rather than streamed as they're written.
+func NewHarWriterWithPages(writer io.Writer, creator *Creator) (*HarWriter, error) {
+	return &HarWriter{
+		writer:       writer,
+		creator:      creator,
+		pagesEnabled: true,
+	}, nil
+}
+
+// StartPage adds a page to the "pages" array written at Close. It only makes
+// sense on a HarWriter constructed via NewHarWriterWithPages. WriteEntry
+// takes no context and does not add a "pageref" itself: an entry is grouped
+// under a page only if its JSON already carries one (as entries produced
+// with WithPage by RoundTripper or Handler do).
+func (w *HarWriter) StartPage(id, title string) *Page {
+	page := &Page{ID: id, Title: title, StartedDateTime: Time(time.Now())}
+
+	w.mut.Lock()
+	w.pages = append(w.pages, page)
+	w.mut.Unlock()
+
+	return page
+}
+
 func (w *HarWriter) WriteEntry(entry json.RawMessage) error {
 	w.mut.Lock()
 	defer w.mut.Unlock()
@@ -53,6 +89,11 @@ func (w *HarWriter) WriteEntry(entry json.RawMessage) error {
 		return fmt.Errorf("HarWriter already closed")
 	}
 
+	if w.pagesEnabled {
+		w.buffered = append(w.buffered, entry)
+		return nil
+	}
+
 	if !w.first {
 		_, err := w.writer.Write([]byte(",\n"))
 		if err != nil {
@@ -79,6 +120,11 @@ func (w *HarWriter) Close() error {
 	}
 
 	w.closed = true
+
+	if w.pagesEnabled {
+		return writeBufferedLog(w.writer, w.creator, w.pages, w.buffered)
+	}
+
 	_, err := w.writer.Write([]byte("\n]}}"))
 	if err != nil {
 		return fmt.Errorf("closing har writer: %w", err)